@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// BitBoard is a performance-oriented alternative to Board for large
+// grids: each generation is two bitplanes (64 cells/word) instead of one
+// byte per cell, and Step advances the whole board by counting neighbors
+// with bitwise adders over whole words instead of allocating a fresh
+// neighbor-count board every generation. It only plays the standard B3/S23 rule — the
+// bit tricks below are specific to that rule's birth/survive thresholds.
+type BitBoard struct {
+	Width       int // must be a multiple of 64
+	Height      int
+	WordsPerRow int
+	planes      [2][]uint64
+	cur         int
+}
+
+// NewBitBoard allocates a toroidal board of width x height cells. width
+// must be a multiple of 64.
+func NewBitBoard(width, height int) (*BitBoard, error) {
+	if width%64 != 0 {
+		return nil, fmt.Errorf("bitboard: width %d is not a multiple of 64", width)
+	}
+	wpr := width / 64
+	return &BitBoard{
+		Width:       width,
+		Height:      height,
+		WordsPerRow: wpr,
+		planes:      [2][]uint64{make([]uint64, wpr*height), make([]uint64, wpr*height)},
+	}, nil
+}
+
+func (bb *BitBoard) row(gen, y int) []uint64 {
+	off := y * bb.WordsPerRow
+	return bb.planes[gen][off : off+bb.WordsPerRow]
+}
+
+// RandomFill fills the board from rng with roughly 25% of cells alive,
+// matching the density RandomFill gives the Cell-based Board.
+func (bb *BitBoard) RandomFill(rng *rand.Rand) {
+	for y := 0; y < bb.Height; y++ {
+		row := bb.row(bb.cur, y)
+		for i := range row {
+			row[i] = rng.Uint64() & rng.Uint64()
+		}
+	}
+}
+
+// Alive reports whether (x, y) is alive in the current generation.
+func (bb *BitBoard) Alive(x, y int) bool {
+	word := bb.row(bb.cur, y)[x/64]
+	return word&(1<<uint(x%64)) != 0
+}
+
+// ToRows renders the current generation into the byte-per-cell Row
+// representation, e.g. to feed it through rle.Write or Board.Colonies.
+func (bb *BitBoard) ToRows() []Row {
+	rows := make([]Row, bb.Height)
+	for y := 0; y < bb.Height; y++ {
+		row := make(Row, bb.Width)
+		for x := 0; x < bb.Width; x++ {
+			if bb.Alive(x, y) {
+				row[x] = CellAlive
+			}
+		}
+		rows[y] = row
+	}
+	return rows
+}
+
+// shiftColumnsRight returns, for every column c, the bit that was at
+// column c-1 (wrapping column 0's predecessor to the last column). It is
+// the "west neighbor" bitplane of row.
+func shiftColumnsRight(row []uint64) []uint64 {
+	n := len(row)
+	out := make([]uint64, n)
+	carry := row[n-1] >> 63
+	for i := 0; i < n; i++ {
+		out[i] = (row[i] << 1) | carry
+		carry = row[i] >> 63
+	}
+	return out
+}
+
+// shiftColumnsLeft returns, for every column c, the bit that was at
+// column c+1 (wrapping the last column's successor to column 0). It is
+// the "east neighbor" bitplane of row.
+func shiftColumnsLeft(row []uint64) []uint64 {
+	n := len(row)
+	out := make([]uint64, n)
+	carry := row[0] & 1
+	for i := n - 1; i >= 0; i-- {
+		out[i] = (row[i] >> 1) | (carry << 63)
+		carry = row[i] & 1
+	}
+	return out
+}
+
+// addBit folds one more 1-bit-per-cell plane into a running 3-bitplane
+// sum (c0 least significant) via a ripple of half adders. The count
+// overflowing bit 2 (i.e. a neighbor count of 8) is dropped: the sum
+// wraps mod 8, which never collides with the counts birth/survive
+// actually test for (2 and 3), since 8 mod 8 is 0.
+func addBit(c0, c1, c2, x uint64) (uint64, uint64, uint64) {
+	s0 := c0 ^ x
+	carry0 := c0 & x
+	s1 := c1 ^ carry0
+	carry1 := c1 & carry0
+	s2 := c2 ^ carry1
+	return s0, s1, s2
+}
+
+// Step advances the board by one generation in place, counting each
+// cell's 8 neighbors by folding the eight shifted neighbor planes into a
+// 3-bitplane counter one plane at a time with addBit (not a carry-save
+// adder tree), wrapping both rows (toroidally, mod Height) and columns
+// (within each row, via shiftColumnsLeft/Right).
+func (bb *BitBoard) Step() {
+	next := bb.cur ^ 1
+	for y := 0; y < bb.Height; y++ {
+		rowN := bb.row(bb.cur, (y-1+bb.Height)%bb.Height)
+		rowC := bb.row(bb.cur, y)
+		rowS := bb.row(bb.cur, (y+1)%bb.Height)
+
+		w, e := shiftColumnsRight(rowC), shiftColumnsLeft(rowC)
+		nw, n, ne := shiftColumnsRight(rowN), rowN, shiftColumnsLeft(rowN)
+		sw, s, se := shiftColumnsRight(rowS), rowS, shiftColumnsLeft(rowS)
+
+		out := bb.row(next, y)
+		for i := 0; i < bb.WordsPerRow; i++ {
+			var c0, c1, c2 uint64
+			c0, c1, c2 = addBit(c0, c1, c2, nw[i])
+			c0, c1, c2 = addBit(c0, c1, c2, n[i])
+			c0, c1, c2 = addBit(c0, c1, c2, ne[i])
+			c0, c1, c2 = addBit(c0, c1, c2, w[i])
+			c0, c1, c2 = addBit(c0, c1, c2, e[i])
+			c0, c1, c2 = addBit(c0, c1, c2, sw[i])
+			c0, c1, c2 = addBit(c0, c1, c2, s[i])
+			c0, c1, c2 = addBit(c0, c1, c2, se[i])
+
+			alive := rowC[i]
+			survive := c1 &^ c2   // neighbor count is 2 or 3
+			birth := c0 & survive // neighbor count is exactly 3
+			out[i] = (alive & survive) | (^alive & birth)
+		}
+	}
+	bb.cur = next
+}
+
+// BitGame is the ebiten.Game driving the --backend=bitset performance
+// mode. It only plays back the simulation: the mouse/keyboard editing,
+// rule switching, colony overlay and netplay that Game offers are not
+// (yet) wired up for this backend.
+type BitGame struct {
+	Board  *BitBoard
+	Paused bool
+	Speed  int
+}
+
+// NewBitGame constructs a bitset-backed game of nColumns x nRows cells,
+// seeded from rng.
+func NewBitGame(nColumns, nRows int, rng *rand.Rand) (*BitGame, error) {
+	board, err := NewBitBoard(nColumns, nRows)
+	if err != nil {
+		return nil, err
+	}
+	board.RandomFill(rng)
+	g := &BitGame{Board: board, Speed: 10}
+	ebiten.SetTPS(g.Speed)
+	return g, nil
+}
+
+func (g *BitGame) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.Paused = !g.Paused
+	}
+	if g.Paused {
+		return nil
+	}
+	g.Board.Step()
+	return nil
+}
+
+func (g *BitGame) Draw(screen *ebiten.Image) {
+	empty := Colors[CellEmpty]
+	alive := Colors[CellAlive]
+	for y := 0; y < g.Board.Height; y++ {
+		for x := 0; x < g.Board.Width; x++ {
+			clr := empty
+			if g.Board.Alive(x, y) {
+				clr = alive
+			}
+			screen.Set(x, y, clr)
+		}
+	}
+}
+
+func (g *BitGame) Layout(oW, oH int) (int, int) {
+	return g.Board.Width, g.Board.Height
+}
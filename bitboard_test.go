@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+// benchWidth and benchHeight match the large-grid case the --backend=bitset
+// mode targets, so BenchmarkStep and BenchmarkFeed compare the two backends
+// on the same board size.
+const (
+	benchWidth  = 2048
+	benchHeight = 1024
+)
+
+// TestStepMatchesNaive checks BitBoard.Step's bitwise-adder neighbor
+// count against a naive reference over many generations, including the
+// toroidal row/column wrap, since Step's bit tricks are easy to get subtly
+// wrong at the edges.
+func TestStepMatchesNaive(t *testing.T) {
+	const width, height = 128, 64
+	const generations = 40
+
+	bb, err := NewBitBoard(width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb.RandomFill(rand.New(rand.NewChaCha8([32]byte{1})))
+
+	grid := make([][]bool, height)
+	for y := range grid {
+		grid[y] = make([]bool, width)
+		for x := range grid[y] {
+			grid[y][x] = bb.Alive(x, y)
+		}
+	}
+
+	for gen := 1; gen <= generations; gen++ {
+		grid = naiveStep(grid)
+		bb.Step()
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if got, want := bb.Alive(x, y), grid[y][x]; got != want {
+					t.Fatalf("generation %d: Alive(%d, %d) = %v, want %v", gen, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+// naiveStep computes the next B3/S23 generation of grid by straightforward
+// per-cell neighbor counting, wrapping both axes toroidally.
+func naiveStep(grid [][]bool) [][]bool {
+	height := len(grid)
+	width := len(grid[0])
+	next := make([][]bool, height)
+	for y := range next {
+		next[y] = make([]bool, width)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+					ny := (y + dy + height) % height
+					nx := (x + dx + width) % width
+					if grid[ny][nx] {
+						n++
+					}
+				}
+			}
+			if grid[y][x] {
+				next[y][x] = n == 2 || n == 3
+			} else {
+				next[y][x] = n == 3
+			}
+		}
+	}
+	return next
+}
+
+// BenchmarkStep times one generation of the bitset backend.
+func BenchmarkStep(b *testing.B) {
+	bb, err := NewBitBoard(benchWidth, benchHeight)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bb.RandomFill(rand.New(rand.NewChaCha8([32]byte{})))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bb.Step()
+	}
+}
+
+// BenchmarkFeed times one generation of the byte-per-cell backend (the
+// feed/lifeOn pair Update alternates between), the same work Step
+// replaces for --backend=bitset.
+func BenchmarkFeed(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	board := NewBoard(benchWidth, benchHeight)
+	board.RandomFill(rand.New(rand.NewChaCha8([32]byte{})))
+	g := &Game{Board: board, Rule: ConwayRule}
+	// RandomFill scatters all four Cell values; settle it to just
+	// CellEmpty/CellAlive, as lifeOn leaves the board between real
+	// generations, before timing the feed/lifeOn cycle.
+	g.lifeOn()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.feed()
+		g.lifeOn()
+	}
+}
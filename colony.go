@@ -0,0 +1,203 @@
+package main
+
+// Colony describes one connected group of live cells, using 8-neighbor
+// adjacency wrapped across the board's toroidal edges.
+type Colony struct {
+	ID                   int
+	Size                 int
+	MinX, MinY           int
+	MaxX, MaxY           int
+	CentroidX, CentroidY float64
+}
+
+// ColonyMap is the result of labeling every live cell on a board with the
+// id of the colony it belongs to.
+type ColonyMap struct {
+	Width    int
+	Height   int
+	ids      []int // row-major, 0 = no colony
+	Colonies []Colony
+}
+
+// ColonyAt returns the colony id at (x, y), or 0 if the cell is empty.
+func (m *ColonyMap) ColonyAt(x, y int) int {
+	return m.ids[y*m.Width+x]
+}
+
+// Largest returns the colony with the most cells, or false if there are
+// no live colonies.
+func (m *ColonyMap) Largest() (Colony, bool) {
+	var best Colony
+	for _, c := range m.Colonies {
+		if c.Size > best.Size {
+			best = c
+		}
+	}
+	return best, best.Size > 0
+}
+
+// Colonies computes the ColonyMap for the board's current state, via
+// two-pass connected-component labeling with union-find, wrapping
+// adjacency across the board's toroidal edges.
+func (b *Board) Colonies() *ColonyMap {
+	height := len(b.Rows)
+	width := len(b.Rows[0])
+	idx := func(x, y int) int { return y*width + x }
+
+	// Pass 1: raster-scan labeling against the four already-visited
+	// neighbors, recording label equivalences via union-find.
+	labels := make([]int, width*height)
+	uf := newUnionFind(width*height + 1)
+	next := 1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if b.Rows[y][x] != CellAlive {
+				continue
+			}
+			var seen []int
+			consider := func(nx, ny int) {
+				if nx < 0 || nx >= width || ny < 0 {
+					return
+				}
+				if l := labels[idx(nx, ny)]; l != 0 {
+					seen = append(seen, l)
+				}
+			}
+			consider(x-1, y)
+			consider(x-1, y-1)
+			consider(x, y-1)
+			consider(x+1, y-1)
+			if len(seen) == 0 {
+				labels[idx(x, y)] = next
+				next++
+				continue
+			}
+			min := seen[0]
+			for _, l := range seen[1:] {
+				uf.union(seen[0], l)
+				if l < min {
+					min = l
+				}
+			}
+			labels[idx(x, y)] = min
+		}
+	}
+
+	// The raster scan above never looks past row 0 or column 0, so the
+	// wrap-around adjacency across the top/bottom and left/right edges
+	// has to be merged in separately.
+	for x := 0; x < width; x++ {
+		top := labels[idx(x, 0)]
+		if top == 0 {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			if bottom := labels[idx(((x+dx)%width+width)%width, height-1)]; bottom != 0 {
+				uf.union(top, bottom)
+			}
+		}
+	}
+	for y := 0; y < height; y++ {
+		left := labels[idx(0, y)]
+		if left == 0 {
+			continue
+		}
+		for dy := -1; dy <= 1; dy++ {
+			if right := labels[idx(width-1, ((y+dy)%height+height)%height)]; right != 0 {
+				uf.union(left, right)
+			}
+		}
+	}
+
+	// Pass 2: resolve each provisional label to its root, compact roots
+	// into contiguous colony ids, and accumulate per-colony stats.
+	m := &ColonyMap{Width: width, Height: height, ids: make([]int, width*height)}
+	var sumX, sumY []int
+	rootToID := make(map[int]int)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			l := labels[idx(x, y)]
+			if l == 0 {
+				continue
+			}
+			root := uf.find(l)
+			id, ok := rootToID[root]
+			if !ok {
+				id = len(m.Colonies) + 1
+				rootToID[root] = id
+				m.Colonies = append(m.Colonies, Colony{ID: id, MinX: x, MinY: y, MaxX: x, MaxY: y})
+				sumX = append(sumX, 0)
+				sumY = append(sumY, 0)
+			}
+			c := &m.Colonies[id-1]
+			c.Size++
+			if x < c.MinX {
+				c.MinX = x
+			}
+			if x > c.MaxX {
+				c.MaxX = x
+			}
+			if y < c.MinY {
+				c.MinY = y
+			}
+			if y > c.MaxY {
+				c.MaxY = y
+			}
+			sumX[id-1] += x
+			sumY[id-1] += y
+			m.ids[idx(x, y)] = id
+		}
+	}
+	for i := range m.Colonies {
+		c := &m.Colonies[i]
+		c.CentroidX = float64(sumX[i]) / float64(c.Size)
+		c.CentroidY = float64(sumY[i]) / float64(c.Size)
+	}
+	return m
+}
+
+// unionFind is a disjoint-set structure over the integers [0, n), with
+// path compression and union by rank.
+type unionFind struct {
+	parent []int
+	rank   []int
+}
+
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), rank: make([]int, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b int) {
+	ra, rb := u.find(a), u.find(b)
+	if ra == rb {
+		return
+	}
+	switch {
+	case u.rank[ra] < u.rank[rb]:
+		u.parent[ra] = rb
+	case u.rank[ra] > u.rank[rb]:
+		u.parent[rb] = ra
+	default:
+		u.parent[rb] = ra
+		u.rank[ra]++
+	}
+}
+
+// colonyColor derives a stable, visually distinct color from a colony id
+// by hashing it into the RGB space.
+func colonyColor(id int) (r, g, b uint8) {
+	h := uint32(id) * 2654435761 // Knuth's multiplicative hash constant
+	return uint8(h >> 24), uint8(h >> 16), uint8(h >> 8)
+}
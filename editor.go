@@ -0,0 +1,90 @@
+package main
+
+// UndoDepth bounds how many strokes the editor can undo.
+const UndoDepth = 20
+
+// paintBrush sets every cell within the current brush radius of (cx, cy)
+// to val, clipped to the board.
+func (g *Game) paintBrush(cx, cy int, val Cell) {
+	half := g.Brush - 1
+	width, height := len(g.Board.Rows[0]), len(g.Board.Rows)
+	for dy := -half; dy <= half; dy++ {
+		y := cy + dy
+		if y < 0 || y >= height {
+			continue
+		}
+		for dx := -half; dx <= half; dx++ {
+			x := cx + dx
+			if x < 0 || x >= width {
+				continue
+			}
+			g.Board.Rows[y][x] = val
+		}
+	}
+}
+
+// copySelection snapshots the board's current selection rectangle into
+// the clipboard, ready for pasteClipboard.
+func (g *Game) copySelection() {
+	x0, x1 := minMax(g.SelStartX, g.SelEndX)
+	y0, y1 := minMax(g.SelStartY, g.SelEndY)
+	w, h := x1-x0+1, y1-y0+1
+	clip := make([][]Cell, h)
+	for i := 0; i < h; i++ {
+		row := make([]Cell, w)
+		copy(row, g.Board.Rows[y0+i][x0:x0+w])
+		clip[i] = row
+	}
+	g.Clipboard = clip
+}
+
+// pasteClipboard stamps the clipboard onto the board with its top-left
+// corner at the cursor, clipping anything that falls outside the board.
+func (g *Game) pasteClipboard() {
+	width, height := len(g.Board.Rows[0]), len(g.Board.Rows)
+	for y, row := range g.Clipboard {
+		by := g.CursorY + y
+		if by < 0 || by >= height {
+			continue
+		}
+		for x, cell := range row {
+			bx := g.CursorX + x
+			if bx < 0 || bx >= width {
+				continue
+			}
+			g.Board.Rows[by][bx] = cell
+		}
+	}
+}
+
+// pushUndo snapshots the board onto the undo stack, dropping the oldest
+// snapshot once the stack is deeper than UndoDepth.
+func (g *Game) pushUndo() {
+	snapshot := make([]Row, len(g.Board.Rows))
+	for i, row := range g.Board.Rows {
+		r := make(Row, len(row))
+		copy(r, row)
+		snapshot[i] = r
+	}
+	g.undoStack = append(g.undoStack, snapshot)
+	if len(g.undoStack) > UndoDepth {
+		g.undoStack = g.undoStack[1:]
+	}
+}
+
+// popUndo restores the most recent undo snapshot, if any.
+func (g *Game) popUndo() {
+	if len(g.undoStack) == 0 {
+		return
+	}
+	last := len(g.undoStack) - 1
+	g.Board.Rows = g.undoStack[last]
+	g.undoStack = g.undoStack[:last]
+}
+
+func minMax(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
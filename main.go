@@ -1,12 +1,22 @@
 package main
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"log"
-	"math/rand"
+	"math/rand/v2"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	netplay "github.com/bukind/life/net"
+	"github.com/bukind/life/rle"
 )
 
 const (
@@ -54,35 +64,453 @@ func NewBoard(nColumns, nRows int) *Board {
 	}
 }
 
-func (b *Board) RandomFill() {
+// RandomFill fills the board from rng, so that two games seeded with the
+// same rng produce identical starting boards.
+func (b *Board) RandomFill(rng *rand.Rand) {
+	for _, row := range b.Rows {
+		for j := range row {
+			row[j] = Cell(rng.IntN(len(Colors)))
+		}
+	}
+}
+
+// LoadRLE replaces the board's contents with the pattern read from r,
+// which must be in the standard Game of Life Run-Length Encoded format.
+// The pattern is placed at the top-left corner; any area outside it is
+// cleared.
+func (b *Board) LoadRLE(r io.Reader) error {
+	p, err := rle.Parse(r)
+	if err != nil {
+		return err
+	}
 	for _, row := range b.Rows {
 		for j := range row {
-			row[j] = Cell(rand.Intn(len(Colors)))
+			row[j] = CellEmpty
 		}
 	}
+	return b.stampPattern(p, 0, 0)
+}
+
+// SaveRLE writes the board's live cells to w in RLE format.
+func (b *Board) SaveRLE(w io.Writer) error {
+	p := &rle.Pattern{
+		Width:  len(b.Rows[0]),
+		Height: len(b.Rows),
+		Cells:  make([]byte, len(b.Rows)*len(b.Rows[0])),
+	}
+	for y, row := range b.Rows {
+		for x, cell := range row {
+			if cell == CellAlive {
+				p.Cells[y*p.Width+x] = 1
+			}
+		}
+	}
+	return rle.Write(w, p)
+}
+
+// stampPattern draws p onto the board with its top-left corner at (ox, oy),
+// clipping anything that falls outside the board.
+func (b *Board) stampPattern(p *rle.Pattern, ox, oy int) error {
+	height, width := len(b.Rows), len(b.Rows[0])
+	for y := 0; y < p.Height; y++ {
+		by := oy + y
+		if by < 0 || by >= height {
+			continue
+		}
+		for x := 0; x < p.Width; x++ {
+			bx := ox + x
+			if bx < 0 || bx >= width {
+				continue
+			}
+			if p.Alive(x, y) {
+				b.Rows[by][bx] = CellAlive
+			}
+		}
+	}
+	return nil
 }
 
 type Game struct {
-	Board     *Board
-	FeedPhase bool
-	Paused    bool
-	Speed     int
+	Board      *Board
+	Rule       Rule
+	RuleIdx    int
+	FeedPhase  bool
+	Paused     bool
+	Speed      int
+	CursorX    int
+	CursorY    int
+	PatternIdx int
+
+	// Colonies is the connected-component labeling of the board as of
+	// the last lifeOn, used by ShowColonies to tint Draw and the HUD.
+	Colonies     *ColonyMap
+	ShowColonies bool
+
+	// Editor state, active while Paused.
+	Brush                int
+	Selecting            bool
+	SelStartX, SelStartY int
+	SelEndX, SelEndY     int
+	HasSelection         bool
+	Clipboard            [][]Cell
+	strokeActive         bool
+	undoStack            [][]Row
+
+	rngSrc *rand.ChaCha8
+	Rng    *rand.Rand
+
+	// Net, if non-nil, hands local inputs off to a rollback-netcode
+	// session instead of advancing the frame directly; see net.Session.
+	Net *netplay.Session
+
+	// SavePath, if non-empty, is where Ctrl+S writes the board as RLE.
+	// Saving is local-only: it doesn't touch any state synced by Net, so
+	// it bypasses the Input pipeline instead of going through AdvanceFrame.
+	SavePath string
 }
 
-func NewGame(nColumns, nRows int) *Game {
+// NewGame constructs a game seeded from seed, so that two peers given the
+// same seed (and the same sequence of inputs) stay in lock-step.
+func NewGame(nColumns, nRows int, rule Rule, seed [32]byte) *Game {
+	src := rand.NewChaCha8(seed)
 	g := &Game{
 		Board:     NewBoard(nColumns, nRows),
+		Rule:      rule,
 		FeedPhase: false,
 		Paused:    false,
 		Speed:     10,
+		CursorX:   nColumns / 2,
+		CursorY:   nRows / 2,
+		Brush:     1,
+		rngSrc:    src,
+		Rng:       rand.New(src),
 	}
-	g.Board.RandomFill()
+	g.Board.RandomFill(g.Rng)
 	ebiten.SetTPS(g.Speed)
 	return g
 }
 
+// moveCursor shifts the pattern-stamp cursor by (dx, dy), clamped to the
+// board bounds.
+func (g *Game) moveCursor(dx, dy int) {
+	width, height := len(g.Board.Rows[0]), len(g.Board.Rows)
+	g.CursorX = clamp(g.CursorX+dx, 0, width-1)
+	g.CursorY = clamp(g.CursorY+dy, 0, height-1)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// stampSelectedPattern stamps the catalogue pattern at PatternIdx onto the
+// board, centered on the cursor.
+func (g *Game) stampSelectedPattern() {
+	entry := rle.Catalogue[g.PatternIdx]
+	p, err := rle.Lookup(entry.Name)
+	if err != nil {
+		log.Printf("stampSelectedPattern: %v", err)
+		return
+	}
+	ox := g.CursorX - p.Width/2
+	oy := g.CursorY - p.Height/2
+	if err := g.Board.stampPattern(p, ox, oy); err != nil {
+		log.Printf("stampSelectedPattern: %v", err)
+	}
+}
+
+// Input is everything a single player can do on a single tick. It is the
+// unit GGPO synchronizes between peers: Update never mutates the board or
+// Game state directly, it only samples local devices into an Input, which
+// is then applied (locally, or once both peers' Inputs are confirmed by
+// net.Session) inside AdvanceFrame.
+type Input struct {
+	CursorDX, CursorDY int8
+	TogglePause        bool
+	CyclePattern       bool
+	StampPattern       bool
+	CycleRule          bool
+	ToggleColonies     bool
+	SpeedDelta         int8
+
+	// Editor fields, sampled only while Paused.
+	MouseX, MouseY int16 // board cell under the cursor, -1 if outside
+	Paint          bool  // left mouse button held
+	Erase          bool  // right mouse button held
+	ShiftHeld      bool  // held while dragging out a selection rectangle
+	SetBrush       int8  // 0 = no change, else the new brush size 1-5
+	Copy           bool
+	Paste          bool
+	Undo           bool
+}
+
+// InputWireSize is the byte length Input.Encode produces and DecodeInput
+// expects; it's also what net.Config.InputSize must be set to.
+const InputWireSize = 10
+
+// Encode packs in into the fixed-size wire format net.Session exchanges
+// with the remote peer.
+func (in Input) Encode() []byte {
+	b := make([]byte, InputWireSize)
+	b[0] = byte(in.CursorDX)
+	b[1] = byte(in.CursorDY)
+	b[2] = byte(in.SpeedDelta)
+	var flags1 byte
+	if in.TogglePause {
+		flags1 |= 1 << 0
+	}
+	if in.CyclePattern {
+		flags1 |= 1 << 1
+	}
+	if in.StampPattern {
+		flags1 |= 1 << 2
+	}
+	if in.CycleRule {
+		flags1 |= 1 << 3
+	}
+	if in.ToggleColonies {
+		flags1 |= 1 << 4
+	}
+	b[3] = flags1
+	var flags2 byte
+	if in.Paint {
+		flags2 |= 1 << 0
+	}
+	if in.Erase {
+		flags2 |= 1 << 1
+	}
+	if in.ShiftHeld {
+		flags2 |= 1 << 2
+	}
+	if in.Copy {
+		flags2 |= 1 << 3
+	}
+	if in.Paste {
+		flags2 |= 1 << 4
+	}
+	if in.Undo {
+		flags2 |= 1 << 5
+	}
+	b[4] = flags2
+	b[5] = byte(in.SetBrush)
+	binary.LittleEndian.PutUint16(b[6:8], uint16(in.MouseX))
+	binary.LittleEndian.PutUint16(b[8:10], uint16(in.MouseY))
+	return b
+}
+
+// DecodeInput unpacks the wire format produced by Input.Encode.
+func DecodeInput(b []byte) Input {
+	if len(b) < InputWireSize {
+		return Input{}
+	}
+	flags1, flags2 := b[3], b[4]
+	return Input{
+		CursorDX:       int8(b[0]),
+		CursorDY:       int8(b[1]),
+		SpeedDelta:     int8(b[2]),
+		TogglePause:    flags1&(1<<0) != 0,
+		CyclePattern:   flags1&(1<<1) != 0,
+		StampPattern:   flags1&(1<<2) != 0,
+		CycleRule:      flags1&(1<<3) != 0,
+		ToggleColonies: flags1&(1<<4) != 0,
+		SetBrush:       int8(b[5]),
+		Paint:          flags2&(1<<0) != 0,
+		Erase:          flags2&(1<<1) != 0,
+		ShiftHeld:      flags2&(1<<2) != 0,
+		Copy:           flags2&(1<<3) != 0,
+		Paste:          flags2&(1<<4) != 0,
+		Undo:           flags2&(1<<5) != 0,
+		MouseX:         int16(binary.LittleEndian.Uint16(b[6:8])),
+		MouseY:         int16(binary.LittleEndian.Uint16(b[8:10])),
+	}
+}
+
 func (g *Game) Update() error {
-	g.processInput()
+	ctrlHeld := ebiten.IsKeyPressed(ebiten.KeyControlLeft) || ebiten.IsKeyPressed(ebiten.KeyControlRight)
+	if ctrlHeld && inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.saveRLE()
+	}
+	in := g.collectInput()
+	if g.Net != nil {
+		return g.Net.Tick(in.Encode())
+	}
+	return g.AdvanceFrame([][]byte{in.Encode()})
+}
+
+// collectInput samples the local keyboard into an Input. It does not
+// mutate g: all state changes happen in applyInput, so that a networked
+// game applies exactly the same code path to local and remote inputs.
+func (g *Game) collectInput() Input {
+	var in Input
+
+	// Toggles fire once per press, not once per frame the key is held
+	// (IsKeyPressed is level-triggered, which made Space flicker Paused
+	// every frame it was held down).
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		in.TogglePause = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		in.CycleRule = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		in.ToggleColonies = true
+	}
+
+	in.MouseX, in.MouseY = -1, -1
+	if g.Paused {
+		switch {
+		case ebiten.IsKeyPressed(ebiten.KeyLeft):
+			in.CursorDX = -1
+		case ebiten.IsKeyPressed(ebiten.KeyRight):
+			in.CursorDX = 1
+		}
+		switch {
+		case ebiten.IsKeyPressed(ebiten.KeyUp):
+			in.CursorDY = -1
+		case ebiten.IsKeyPressed(ebiten.KeyDown):
+			in.CursorDY = 1
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+			in.CyclePattern = true
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			in.StampPattern = true
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+			in.Copy = true
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+			in.Paste = true
+		}
+		ctrlHeld := ebiten.IsKeyPressed(ebiten.KeyControlLeft) || ebiten.IsKeyPressed(ebiten.KeyControlRight)
+		if ctrlHeld && inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+			in.Undo = true
+		}
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.Key1):
+			in.SetBrush = 1
+		case inpututil.IsKeyJustPressed(ebiten.Key2):
+			in.SetBrush = 2
+		case inpututil.IsKeyJustPressed(ebiten.Key3):
+			in.SetBrush = 3
+		case inpututil.IsKeyJustPressed(ebiten.Key4):
+			in.SetBrush = 4
+		case inpututil.IsKeyJustPressed(ebiten.Key5):
+			in.SetBrush = 5
+		}
+
+		width, height := len(g.Board.Rows[0]), len(g.Board.Rows)
+		mx, my := ebiten.CursorPosition()
+		if mx >= 0 && mx < width && my >= 0 && my < height {
+			in.MouseX, in.MouseY = int16(mx), int16(my)
+			in.Paint = ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+			in.Erase = ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+			in.ShiftHeld = ebiten.IsKeyPressed(ebiten.KeyShiftLeft) || ebiten.IsKeyPressed(ebiten.KeyShiftRight)
+		}
+	} else {
+		switch {
+		case ebiten.IsKeyPressed(ebiten.KeyLeft):
+			in.SpeedDelta = -1
+		case ebiten.IsKeyPressed(ebiten.KeyRight):
+			in.SpeedDelta = 1
+		}
+	}
+
+	return in
+}
+
+// applyInput mutates g according to in. It is called from AdvanceFrame for
+// every player's input, in player order, before the board is stepped.
+func (g *Game) applyInput(in Input) {
+	if in.TogglePause {
+		g.Paused = !g.Paused
+	}
+	if in.CursorDX != 0 || in.CursorDY != 0 {
+		g.moveCursor(int(in.CursorDX), int(in.CursorDY))
+	}
+	if in.CyclePattern {
+		g.PatternIdx = (g.PatternIdx + 1) % len(rle.Catalogue)
+	}
+	if in.StampPattern {
+		g.pushUndo()
+		g.stampSelectedPattern()
+	}
+	if in.CycleRule {
+		g.RuleIdx = (g.RuleIdx + 1) % len(RulePresets)
+		g.Rule = RulePresets[g.RuleIdx].Rule
+		log.Printf("rule: %s", RulePresets[g.RuleIdx].Name)
+	}
+	if in.ToggleColonies {
+		g.ShowColonies = !g.ShowColonies
+	}
+	if in.SpeedDelta != 0 {
+		g.Speed += int(in.SpeedDelta)
+		if g.Speed < 1 {
+			g.Speed = 1
+		}
+		if g.Speed > MaxTPS {
+			g.Speed = MaxTPS
+		}
+		ebiten.SetTPS(g.Speed)
+	}
+	if in.SetBrush != 0 {
+		g.Brush = int(in.SetBrush)
+	}
+
+	if g.Paused && in.MouseX >= 0 && in.MouseY >= 0 {
+		x, y := int(in.MouseX), int(in.MouseY)
+		g.CursorX, g.CursorY = x, y
+		switch {
+		case in.ShiftHeld && (in.Paint || in.Erase):
+			if !g.Selecting {
+				g.Selecting = true
+				g.SelStartX, g.SelStartY = x, y
+			}
+			g.SelEndX, g.SelEndY = x, y
+		case in.Paint || in.Erase:
+			if !g.strokeActive {
+				g.pushUndo()
+			}
+			val := CellAlive
+			if in.Erase {
+				val = CellEmpty
+			}
+			g.paintBrush(x, y, val)
+			g.Selecting = false
+		default:
+			if g.Selecting {
+				g.HasSelection = true
+			}
+			g.Selecting = false
+		}
+	}
+	g.strokeActive = in.Paint || in.Erase
+
+	if in.Copy && g.HasSelection {
+		g.copySelection()
+	}
+	if in.Paste && g.Clipboard != nil {
+		g.pushUndo()
+		g.pasteClipboard()
+	}
+	if in.Undo {
+		g.popUndo()
+	}
+}
+
+// AdvanceFrame applies every player's input for this tick, then steps the
+// board if the game isn't paused. It implements net.Simulation so a
+// net.Session can invoke it with the inputs GGPO has confirmed for the
+// frame, including during rollback resimulation.
+func (g *Game) AdvanceFrame(inputs [][]byte) error {
+	for _, raw := range inputs {
+		g.applyInput(DecodeInput(raw))
+	}
 	if g.Paused {
 		return nil
 	}
@@ -94,23 +522,98 @@ func (g *Game) Update() error {
 	return g.lifeOn()
 }
 
-func (g *Game) processInput() {
-	switch {
-	case ebiten.IsKeyPressed(ebiten.KeySpace):
-		g.Paused = !g.Paused
-	case ebiten.IsKeyPressed(ebiten.KeyLeft):
-		g.Speed--
-		if g.Speed < 1 {
-			g.Speed = 1
+// SaveGameState packs the board (2 bits/cell, since CellBorn/CellDying can
+// be live mid-frame), the RNG stream, and FeedPhase, Paused and Speed. The
+// rule, cursor, pattern and brush selection, colony overlay toggle and
+// editor state (selection, clipboard, undo stack) are not saved, so
+// LoadGameState leaves them as they are. It implements net.Simulation.
+func (g *Game) SaveGameState() ([]byte, error) {
+	rngState, err := g.rngSrc.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("saving rng state: %w", err)
+	}
+	width, height := len(g.Board.Rows[0]), len(g.Board.Rows)
+	cells := packCells(g.Board.Rows)
+
+	var buf []byte
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(width))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(height))
+	buf = append(buf, boolByte(g.FeedPhase), boolByte(g.Paused))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(g.Speed))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(rngState)))
+	buf = append(buf, rngState...)
+	buf = append(buf, cells...)
+	return buf, nil
+}
+
+// LoadGameState restores state saved by SaveGameState. It implements
+// net.Simulation.
+func (g *Game) LoadGameState(state []byte) error {
+	r := state
+	if len(r) < 18 {
+		return fmt.Errorf("game state too short: %d bytes", len(r))
+	}
+	width := int(binary.LittleEndian.Uint32(r[0:4]))
+	height := int(binary.LittleEndian.Uint32(r[4:8]))
+	g.FeedPhase = r[8] != 0
+	g.Paused = r[9] != 0
+	g.Speed = int(binary.LittleEndian.Uint32(r[10:14]))
+	r = r[14:]
+	rngLen := int(binary.LittleEndian.Uint32(r[0:4]))
+	r = r[4:]
+	if len(r) < rngLen {
+		return fmt.Errorf("game state truncated rng state")
+	}
+	if err := g.rngSrc.UnmarshalBinary(r[:rngLen]); err != nil {
+		return fmt.Errorf("loading rng state: %w", err)
+	}
+	r = r[rngLen:]
+	return unpackCells(r, width, height, g.Board.Rows)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// packCells packs rows (one Cell, values 0-3, per board cell) 4-to-a-byte.
+func packCells(rows []Row) []byte {
+	width, height := len(rows[0]), len(rows)
+	buf := make([]byte, (width*height+3)/4)
+	idx := 0
+	for _, row := range rows {
+		for _, cell := range row {
+			buf[idx/4] |= byte(cell&3) << uint((idx%4)*2)
+			idx++
 		}
-		ebiten.SetTPS(g.Speed)
-	case ebiten.IsKeyPressed(ebiten.KeyRight):
-		g.Speed++
-		if g.Speed > MaxTPS {
-			g.Speed = MaxTPS
+	}
+	return buf
+}
+
+// unpackCells is the inverse of packCells, writing into rows.
+func unpackCells(buf []byte, width, height int, rows []Row) error {
+	if len(rows) != height || len(rows[0]) != width {
+		return fmt.Errorf("game state board is %dx%d, want %dx%d", width, height, len(rows[0]), len(rows))
+	}
+	idx := 0
+	for _, row := range rows {
+		for j := range row {
+			row[j] = Cell((buf[idx/4] >> uint((idx%4)*2)) & 3)
+			idx++
 		}
-		ebiten.SetTPS(g.Speed)
 	}
+	return nil
+}
+
+// alive projects a cell onto 1 if it's alive, 0 otherwise, independently
+// of the CellBorn/CellDying transition states.
+func alive(c Cell) Cell {
+	if c == CellAlive {
+		return 1
+	}
+	return 0
 }
 
 func (g *Game) feed() error {
@@ -127,9 +630,8 @@ func (g *Game) feed() error {
 			i2 = 0
 		}
 		// Make sum of three adjacent rows into tmp.
-		for j, cell := range row {
-			count := cell + rows[i1][j] + rows[i2][j]
-			tmp[j] = count
+		for j := range row {
+			tmp[j] = alive(row[j]) + alive(rows[i1][j]) + alive(rows[i2][j])
 		}
 		// Make sum of three adjacent columns into crow.
 		crow := make(Row, len(row))
@@ -143,21 +645,21 @@ func (g *Game) feed() error {
 				j2 = 0
 			}
 			// We count all 9 cells, minus the central one.
-			crow[j] = cnt + tmp[j1] + tmp[j2] - row[j]
+			crow[j] = cnt + tmp[j1] + tmp[j2] - alive(row[j])
 		}
 		countBoard = append(countBoard, crow)
 	}
 	// We make a final sweep to check the fate of the cells.
 	for i, row := range rows {
 		for j, cell := range row {
-			nbrs := countBoard[i][j]
+			nbrs := uint(countBoard[i][j])
 			switch cell {
 			case CellEmpty:
-				if nbrs == 3 {
+				if g.Rule.Birth&(1<<nbrs) != 0 {
 					row[j] = CellBorn
 				}
 			case CellAlive:
-				if nbrs < 2 || nbrs > 3 {
+				if g.Rule.Survive&(1<<nbrs) == 0 {
 					row[j] = CellDying
 				}
 			default:
@@ -186,6 +688,7 @@ func (g *Game) lifeOn() error {
 		}
 	}
 	log.Printf("lifeOn: empty=%d filled=%d", empty, filled)
+	g.Colonies = g.Board.Colonies()
 	return nil
 }
 
@@ -193,9 +696,32 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	for y, row := range g.Board.Rows {
 		for x, cell := range row {
 			clr := Colors[cell]
+			if g.ShowColonies && g.Colonies != nil {
+				if id := g.Colonies.ColonyAt(x, y); id != 0 {
+					r, gr, b := colonyColor(id)
+					clr = color.RGBA{r, gr, b, 0xff}
+				}
+			}
 			screen.Set(x, y, clr)
 		}
 	}
+	if g.ShowColonies && g.Colonies != nil {
+		largest, _ := g.Colonies.Largest()
+		ebitenutil.DebugPrint(screen, fmt.Sprintf("colonies: %d  largest: %d", len(g.Colonies.Colonies), largest.Size))
+	}
+	if g.Paused && (g.Selecting || g.HasSelection) {
+		selectionColor := color.RGBA{0xff, 0xff, 0x00, 0xff}
+		x0, x1 := minMax(g.SelStartX, g.SelEndX)
+		y0, y1 := minMax(g.SelStartY, g.SelEndY)
+		for x := x0; x <= x1; x++ {
+			screen.Set(x, y0, selectionColor)
+			screen.Set(x, y1, selectionColor)
+		}
+		for y := y0; y <= y1; y++ {
+			screen.Set(x0, y, selectionColor)
+			screen.Set(x1, y, selectionColor)
+		}
+	}
 }
 
 func (g *Game) Layout(oW, oH int) (int, int) {
@@ -203,13 +729,121 @@ func (g *Game) Layout(oW, oH int) (int, int) {
 }
 
 func main() {
+	loadPath := flag.String("load", "", "path to an RLE file to load at startup, instead of a random fill")
+	savePath := flag.String("save", "", "path to write the board as RLE when Ctrl+S is pressed")
+	ruleStr := flag.String("rule", "B3/S23", "the rule to simulate, in B/S notation (e.g. B36/S23 for HighLife)")
+	seedFlag := flag.Uint64("seed", 0, "PRNG seed for the starting board (0 = pick one at random); must match across peers for --connect/--host netplay")
+	localPort := flag.Int("local", 0, "UDP port to bind for netplay (0 disables netplay)")
+	hostAddr := flag.String("host", "", "this peer's own host:port, for the remote to dial with --connect")
+	connectAddr := flag.String("connect", "", "remote peer's host:port to connect to for two-player netplay")
+	backend := flag.String("backend", "cells", "simulation backend: \"cells\" (one byte/cell, full feature set) or \"bitset\" (packed bitplanes, faster on large grids, B3/S23 only)")
+	flag.Parse()
+	_ = hostAddr // announced to the remote out of band; not otherwise used locally
+
+	rule, err := ParseRule(*ruleStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	seed, err := resolveSeed(*seedFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 	ebiten.SetWindowSize(Columns, Rows)
 	ebiten.SetWindowTitle("convay's life")
 	ebiten.SetVsyncEnabled(true)
 	log.Printf("about to start game")
 	log.Printf("cell values: %d, %d, %d, %d", CellEmpty, CellBorn, CellAlive, CellDying)
-	if err := ebiten.RunGame(NewGame(Columns, Rows)); err != nil {
-		log.Fatal(err)
+
+	switch *backend {
+	case "cells":
+		g := NewGame(Columns, Rows, rule, seed)
+		g.SavePath = *savePath
+		if *loadPath != "" {
+			if err := loadRLEFile(g.Board, *loadPath); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *localPort != 0 {
+			numPlayers := 1
+			if *connectAddr != "" {
+				numPlayers = 2
+			}
+			sess, err := netplay.NewSession(g, netplay.Config{
+				LocalPort:  *localPort,
+				RemoteAddr: *connectAddr,
+				NumPlayers: numPlayers,
+				InputSize:  InputWireSize,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer sess.Close()
+			g.Net = sess
+		}
+		if err := ebiten.RunGame(g); err != nil {
+			log.Fatal(err)
+		}
+	case "bitset":
+		g, err := NewBitGame(Columns, Rows, rand.New(rand.NewChaCha8(seed)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ebiten.RunGame(g); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown --backend %q, want \"cells\" or \"bitset\"", *backend)
+	}
+}
+
+// resolveSeed turns a --seed flag value into a ChaCha8 seed: the flag
+// value repeated to fill 32 bytes if non-zero, otherwise a fresh random
+// seed from the OS.
+func resolveSeed(flagSeed uint64) ([32]byte, error) {
+	var seed [32]byte
+	if flagSeed == 0 {
+		if _, err := cryptorand.Read(seed[:]); err != nil {
+			return seed, fmt.Errorf("generating random seed: %w", err)
+		}
+		return seed, nil
+	}
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(seed[i*8:], flagSeed)
+	}
+	return seed, nil
+}
+
+func loadRLEFile(b *Board, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := b.LoadRLE(f); err != nil {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	return nil
+}
+
+// saveRLE writes the current board to g.SavePath in RLE format, logging
+// instead of failing the game on error: a bad save path shouldn't crash
+// a running session.
+func (g *Game) saveRLE() {
+	if g.SavePath == "" {
+		log.Printf("saveRLE: no --save path configured, ignoring Ctrl+S")
+		return
+	}
+	f, err := os.Create(g.SavePath)
+	if err != nil {
+		log.Printf("saveRLE: %v", err)
+		return
+	}
+	defer f.Close()
+	if err := g.Board.SaveRLE(f); err != nil {
+		log.Printf("saveRLE: %v", err)
+		return
 	}
+	log.Printf("saveRLE: wrote %s", g.SavePath)
 }
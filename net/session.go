@@ -0,0 +1,186 @@
+// Package net wires a deterministic simulation into GGPO rollback
+// netcode (github.com/assemblaj/ggpo), so two peers can share a
+// simulation over UDP while each only has to transmit its own input.
+package net
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/assemblaj/ggpo"
+)
+
+// Simulation is the game loop being synchronized. All of its methods must
+// be deterministic given the same sequence of inputs: GGPO rolls back to
+// a saved state and replays AdvanceFrame to correct mispredictions, so
+// any hidden nondeterminism (wall-clock time, unseeded randomness) will
+// desync the peers.
+type Simulation interface {
+	// AdvanceFrame applies inputs, one entry per player in player order,
+	// and steps the simulation by one frame.
+	AdvanceFrame(inputs [][]byte) error
+	// SaveGameState returns a snapshot of the full simulation state.
+	SaveGameState() ([]byte, error)
+	// LoadGameState restores a snapshot produced by SaveGameState.
+	LoadGameState(state []byte) error
+}
+
+// Config selects how a Session reaches its peer.
+type Config struct {
+	// LocalPort is the UDP port this peer listens on.
+	LocalPort int
+	// RemoteAddr is the "host:port" of the other peer.
+	RemoteAddr string
+	// NumPlayers is the total player count, including the local player.
+	NumPlayers int
+	// InputSize is the byte size of one player's encoded input.
+	InputSize int
+}
+
+// Session owns a peer-to-peer GGPO backend synchronizing a Simulation
+// against a single remote peer. It implements ggpo.Session, so GGPO calls
+// back into it to checkpoint state and to resimulate frames on rollback.
+type Session struct {
+	sim       Simulation
+	backend   ggpo.Peer
+	local     ggpo.PlayerHandle
+	inputSize int
+
+	// states holds the snapshots GGPO has asked us to keep, keyed by its
+	// own stateID. ggpo.Session.LoadGameState only gets the stateID back,
+	// not the bytes, so SaveGameState has to remember them here.
+	states map[int][]byte
+}
+
+// NewSession starts a GGPO peer-to-peer backend for sim, listening on
+// cfg.LocalPort and pairing with the remote at cfg.RemoteAddr.
+func NewSession(sim Simulation, cfg Config) (*Session, error) {
+	s := &Session{
+		sim:       sim,
+		inputSize: cfg.InputSize,
+		states:    make(map[int][]byte),
+	}
+
+	s.backend = ggpo.NewPeer(s, cfg.LocalPort, cfg.NumPlayers, cfg.InputSize)
+	if err := s.backend.InitializeConnection(); err != nil {
+		return nil, fmt.Errorf("net: initializing connection: %w", err)
+	}
+
+	// GGPO needs both peers to agree on player numbering: the side being
+	// dialed into (no --connect) is always player 1 and the side doing
+	// the dialing is always player 2, regardless of which side happens
+	// to call AddPlayer first.
+	localNum, remoteNum := 1, 2
+	if cfg.RemoteAddr != "" {
+		localNum, remoteNum = 2, 1
+	}
+
+	localPlayer := ggpo.NewLocalPlayer(cfg.InputSize, localNum)
+	var local ggpo.PlayerHandle
+	if err := s.backend.AddPlayer(&localPlayer, &local); err != nil {
+		return nil, fmt.Errorf("net: adding local player: %w", err)
+	}
+	s.local = local
+
+	if cfg.RemoteAddr != "" {
+		ip, port, err := splitHostPort(cfg.RemoteAddr)
+		if err != nil {
+			return nil, fmt.Errorf("net: parsing remote addr %q: %w", cfg.RemoteAddr, err)
+		}
+		remotePlayer := ggpo.NewRemotePlayer(cfg.InputSize, remoteNum, ip, port)
+		var remote ggpo.PlayerHandle
+		if err := s.backend.AddPlayer(&remotePlayer, &remote); err != nil {
+			return nil, fmt.Errorf("net: adding remote player: %w", err)
+		}
+	}
+
+	s.backend.Start()
+	return s, nil
+}
+
+// Tick lets GGPO poll the network, submits the local player's input for
+// this frame, then advances the frame with whatever inputs GGPO has
+// synced for it.
+func (s *Session) Tick(localInput []byte) error {
+	if err := s.backend.Idle(0); err != nil {
+		return fmt.Errorf("net: polling: %w", err)
+	}
+	if err := s.backend.AddLocalInput(s.local, localInput, s.inputSize); err != nil {
+		return fmt.Errorf("net: adding local input: %w", err)
+	}
+	var disconnectFlags int
+	inputs, err := s.backend.SyncInput(&disconnectFlags)
+	if err != nil {
+		return fmt.Errorf("net: syncing input: %w", err)
+	}
+	if err := s.sim.AdvanceFrame(inputs); err != nil {
+		return fmt.Errorf("net: advancing frame: %w", err)
+	}
+	if err := s.backend.AdvanceFrame(ggpo.DefaultChecksum); err != nil {
+		return fmt.Errorf("net: confirming frame: %w", err)
+	}
+	return nil
+}
+
+// Close shuts the session down and frees its network resources.
+func (s *Session) Close() error {
+	return s.backend.Close()
+}
+
+// SaveGameState implements ggpo.Session: it snapshots sim under stateID,
+// so a later LoadGameState(stateID) can roll back to it.
+func (s *Session) SaveGameState(stateID int) int {
+	state, err := s.sim.SaveGameState()
+	if err != nil {
+		panic(fmt.Sprintf("net: saving game state: %v", err))
+	}
+	s.states[stateID] = state
+	return ggpo.DefaultChecksum
+}
+
+// LoadGameState implements ggpo.Session: it restores the snapshot taken
+// by SaveGameState(stateID).
+func (s *Session) LoadGameState(stateID int) {
+	state, ok := s.states[stateID]
+	if !ok {
+		panic(fmt.Sprintf("net: no saved state for stateID %d", stateID))
+	}
+	if err := s.sim.LoadGameState(state); err != nil {
+		panic(fmt.Sprintf("net: loading game state: %v", err))
+	}
+}
+
+// AdvanceFrame implements ggpo.Session: GGPO calls this to replay a frame
+// during rollback resimulation, with whatever inputs it has confirmed.
+func (s *Session) AdvanceFrame(flags int) {
+	var disconnectFlags int
+	inputs, err := s.backend.SyncInput(&disconnectFlags)
+	if err != nil {
+		panic(fmt.Sprintf("net: syncing input during rollback: %v", err))
+	}
+	if err := s.sim.AdvanceFrame(inputs); err != nil {
+		panic(fmt.Sprintf("net: advancing frame during rollback: %v", err))
+	}
+}
+
+// OnEvent implements ggpo.Session: GGPO reports connection lifecycle and
+// timing events here.
+func (s *Session) OnEvent(info *ggpo.Event) {
+	log.Printf("net: %+v", info)
+}
+
+// splitHostPort parses a "host:port" address into the separate IP and
+// port fields ggpo.NewRemotePlayer wants.
+func splitHostPort(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad port %q: %w", portStr, err)
+	}
+	return host, port, nil
+}
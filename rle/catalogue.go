@@ -0,0 +1,51 @@
+package rle
+
+import "strings"
+
+// Catalogue lists a handful of classic patterns, keyed by a short
+// lowercase name, encoded in RLE so they parse through the same Parse
+// path as a file loaded from disk.
+var Catalogue = []struct {
+	Name string
+	RLE  string
+}{
+	{"glider", `#N Glider
+#C The smallest, most common spaceship.
+x = 3, y = 3, rule = B3/S23
+bob$2bo$3o!
+`},
+	{"lwss", `#N Lightweight spaceship
+#C The smallest orthogonal spaceship other than the glider.
+x = 5, y = 4, rule = B3/S23
+bo2bo$o4b$o3bo$4ob!
+`},
+	{"gosperglidergun", `#N Gosper glider gun
+#C The first known gun, discovered by Bill Gosper in 1970.
+x = 36, y = 9, rule = B3/S23
+24bo11b$22bobo11b$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o14b$2o8b
+o3bob2o4bobo11b$10bo5bo7bo11b$11bo3bo20b$12b2o!
+`},
+	{"pulsar", `#N Pulsar
+#C A period-3 oscillator.
+x = 13, y = 13, rule = B3/S23
+2b3o3b3o2b2$o4bobo4bo$o4bobo4bo$o4bobo4bo$2b3o3b3o2b2$2b3o3b3o2b$o4bo
+bo4bo$o4bobo4bo$o4bobo4bo2$2b3o3b3o2b!
+`},
+}
+
+// Lookup returns the named pattern parsed from the catalogue, or an error
+// if no pattern with that name exists.
+func Lookup(name string) (*Pattern, error) {
+	for _, e := range Catalogue {
+		if e.Name == name {
+			return Parse(strings.NewReader(e.RLE))
+		}
+	}
+	return nil, errNoSuchPattern(name)
+}
+
+type errNoSuchPattern string
+
+func (e errNoSuchPattern) Error() string {
+	return "rle: no such catalogue pattern: " + string(e)
+}
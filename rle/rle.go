@@ -0,0 +1,225 @@
+// Package rle reads and writes the Run-Length Encoded Game of Life pattern
+// format (the .rle convention used across the cellular-automata community,
+// e.g. by Golly and the LifeWiki).
+package rle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pattern is a parsed RLE pattern: a rectangular grid of cells plus the
+// header metadata that came with it.
+type Pattern struct {
+	Name    string // from a "#N " comment line, if present
+	Comment string // remaining "#C "/"#O " comment lines, joined by "\n"
+	Rule    string // the raw "rule = ..." field, e.g. "B3/S23"
+	Width   int
+	Height  int
+	// Cells is row-major, Width*Height long. A non-zero byte means alive.
+	Cells []byte
+}
+
+// Alive reports whether the cell at (x, y) is alive.
+func (p *Pattern) Alive(x, y int) bool {
+	return p.Cells[y*p.Width+x] != 0
+}
+
+// Parse reads a pattern in RLE format from r.
+func Parse(r io.Reader) (*Pattern, error) {
+	p := &Pattern{}
+	var comments []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var header string
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "#N"):
+			p.Name = strings.TrimSpace(strings.TrimPrefix(line, "#N"))
+		case strings.HasPrefix(line, "#O"), strings.HasPrefix(line, "#C"):
+			comments = append(comments, strings.TrimSpace(line[2:]))
+		case strings.HasPrefix(line, "#"):
+			// Unknown comment kind; keep it verbatim.
+			comments = append(comments, line)
+		case strings.Contains(line, "x") && strings.Contains(line, "="):
+			header = line
+		default:
+			return nil, fmt.Errorf("rle: unexpected line %q before header", line)
+		}
+		if header != "" {
+			break
+		}
+	}
+	if header == "" {
+		return nil, fmt.Errorf("rle: missing header line")
+	}
+	if len(comments) > 0 {
+		p.Comment = strings.Join(comments, "\n")
+	}
+	w, h, rule, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	p.Width, p.Height, p.Rule = w, h, rule
+	p.Cells = make([]byte, w*h)
+
+	var body strings.Builder
+	for sc.Scan() {
+		body.WriteString(strings.TrimSpace(sc.Text()))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := parseBody(body.String(), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseHeader parses a line like "x = 3, y = 3, rule = B3/S23".
+func parseHeader(line string) (w, h int, rule string, err error) {
+	for _, field := range strings.Split(line, ",") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		val := strings.TrimSpace(kv[1])
+		switch key {
+		case "x":
+			if w, err = strconv.Atoi(val); err != nil {
+				return 0, 0, "", fmt.Errorf("rle: bad x: %w", err)
+			}
+		case "y":
+			if h, err = strconv.Atoi(val); err != nil {
+				return 0, 0, "", fmt.Errorf("rle: bad y: %w", err)
+			}
+		case "rule":
+			rule = val
+		}
+	}
+	if w <= 0 || h <= 0 {
+		return 0, 0, "", fmt.Errorf("rle: header missing x/y dimensions")
+	}
+	return w, h, rule, nil
+}
+
+// parseBody parses the b/o/$/! token stream into p.Cells.
+func parseBody(body string, p *Pattern) error {
+	x, y := 0, 0
+	count := 0
+	for _, r := range body {
+		switch {
+		case r >= '0' && r <= '9':
+			count = count*10 + int(r-'0')
+		case r == 'b', r == 'o':
+			n := count
+			if n == 0 {
+				n = 1
+			}
+			if r == 'o' {
+				for i := 0; i < n; i++ {
+					if x+i < p.Width && y < p.Height {
+						p.Cells[y*p.Width+x+i] = 1
+					}
+				}
+			}
+			x += n
+			count = 0
+		case r == '$':
+			n := count
+			if n == 0 {
+				n = 1
+			}
+			y += n
+			x = 0
+			count = 0
+		case r == '!':
+			return nil
+		default:
+			return fmt.Errorf("rle: unexpected token %q in body", r)
+		}
+	}
+	return fmt.Errorf("rle: body missing terminating '!'")
+}
+
+// Write encodes p in RLE format to w.
+func Write(w io.Writer, p *Pattern) error {
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#N %s\n", p.Name)
+	}
+	if p.Comment != "" {
+		for _, line := range strings.Split(p.Comment, "\n") {
+			fmt.Fprintf(bw, "#C %s\n", line)
+		}
+	}
+	rule := p.Rule
+	if rule == "" {
+		rule = "B3/S23"
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d, rule = %s\n", p.Width, p.Height, rule)
+
+	var line strings.Builder
+	writeRun := func(n int, tok byte) {
+		if n == 0 {
+			return
+		}
+		if n > 1 {
+			fmt.Fprintf(&line, "%d", n)
+		}
+		line.WriteByte(tok)
+	}
+	for y := 0; y < p.Height; y++ {
+		runTok := byte(0)
+		runLen := 0
+		for x := 0; x < p.Width; x++ {
+			tok := byte('b')
+			if p.Alive(x, y) {
+				tok = 'o'
+			}
+			if tok == runTok {
+				runLen++
+				continue
+			}
+			writeRun(runLen, runTok)
+			runTok, runLen = tok, 1
+		}
+		if runTok == 'o' {
+			writeRun(runLen, runTok)
+		}
+		if y < p.Height-1 {
+			line.WriteByte('$')
+		} else {
+			line.WriteByte('!')
+		}
+	}
+	if err := writeWrapped(bw, line.String()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeWrapped writes s wrapped at 70 columns, as conventional RLE files do.
+func writeWrapped(w *bufio.Writer, s string) error {
+	const width = 70
+	for len(s) > width {
+		if _, err := w.WriteString(s[:width]); err != nil {
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+		s = s[width:]
+	}
+	_, err := w.WriteString(s + "\n")
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule holds a totalistic Game of Life rule in B/S notation: a cell with
+// n live neighbors (0..8) is born if bit n is set in Birth, and survives
+// if bit n is set in Survive.
+type Rule struct {
+	Birth   uint16
+	Survive uint16
+}
+
+// ConwayRule is the standard B3/S23 ruleset Conway's Life is played under.
+var ConwayRule = Rule{Birth: 1 << 3, Survive: 1<<2 | 1<<3}
+
+// RulePresets are cycled through by the in-game rule key binding.
+var RulePresets = []struct {
+	Name string
+	Rule Rule
+}{
+	{"B3/S23 (Conway)", ConwayRule},
+	{"B36/S23 (HighLife)", Rule{Birth: 1<<3 | 1<<6, Survive: 1<<2 | 1<<3}},
+	{"B3678/S34678 (Day & Night)", Rule{
+		Birth:   1<<3 | 1<<6 | 1<<7 | 1<<8,
+		Survive: 1<<3 | 1<<4 | 1<<6 | 1<<7 | 1<<8,
+	}},
+}
+
+// ParseRule parses the standard B/S rule notation, e.g. "B3/S23" or
+// "B36/S23". The B and S terms may appear in either order.
+func ParseRule(s string) (Rule, error) {
+	var r Rule
+	var sawB, sawS bool
+	for _, term := range strings.Split(s, "/") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		mask, err := parseCountMask(term[1:])
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule: %q: %w", s, err)
+		}
+		switch term[0] {
+		case 'B', 'b':
+			r.Birth = mask
+			sawB = true
+		case 'S', 's':
+			r.Survive = mask
+			sawS = true
+		default:
+			return Rule{}, fmt.Errorf("rule: %q: term %q must start with B or S", s, term)
+		}
+	}
+	if !sawB || !sawS {
+		return Rule{}, fmt.Errorf("rule: %q: must specify both a B and an S term", s)
+	}
+	return r, nil
+}
+
+// parseCountMask turns a string of distinct digits 0-8 into a bitmask.
+func parseCountMask(digits string) (uint16, error) {
+	var mask uint16
+	for _, d := range digits {
+		n, err := strconv.Atoi(string(d))
+		if err != nil || n < 0 || n > 8 {
+			return 0, fmt.Errorf("bad neighbor count %q", d)
+		}
+		mask |= 1 << uint(n)
+	}
+	return mask, nil
+}
+
+// String renders r back into B/S notation.
+func (r Rule) String() string {
+	return fmt.Sprintf("B%s/S%s", countMaskString(r.Birth), countMaskString(r.Survive))
+}
+
+func countMaskString(mask uint16) string {
+	var b strings.Builder
+	for n := 0; n <= 8; n++ {
+		if mask&(1<<uint(n)) != 0 {
+			fmt.Fprintf(&b, "%d", n)
+		}
+	}
+	return b.String()
+}